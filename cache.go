@@ -0,0 +1,96 @@
+package twitch
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MinCacheTTL is the floor applied to every cache entry so callers can't accidentally cache
+// shorter than Twitch's rate limits can tolerate.
+const MinCacheTTL = 30 * time.Second
+
+// An APICache stores decoded-ready response bodies keyed by request identity, letting repeat
+// calls to endpoints like GetUsersByName or GetUserFollows skip the network entirely.
+type APICache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, body []byte, ttl time.Duration)
+}
+
+type cacheEntry struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+// memoryCache is the in-memory APICache that NewClient attaches to every Client; Client has
+// no option for supplying a different APICache.
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewMemoryCache creates an in-memory APICache.
+func NewMemoryCache() APICache {
+	return &memoryCache{
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+func (c *memoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.body, true
+}
+
+func (c *memoryCache) Set(key string, body []byte, ttl time.Duration) {
+	if ttl < MinCacheTTL {
+		ttl = MinCacheTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry{
+		body:      body,
+		expiresAt: time.Now().Add(ttl),
+	}
+}
+
+// cachedResponse wraps a previously cached body back into an *http.Response so that the
+// Get* methods can keep decoding res.Body exactly as they do for a live request.
+func cachedResponse(body []byte) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+// cacheResponse drains res.Body, stores it in cache under key if the request succeeded, and
+// returns a new response with an equivalent, still-unread body.
+func cacheResponse(res *http.Response, cache APICache, key string) (*http.Response, error) {
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusOK {
+		cache.Set(key, body, MinCacheTTL)
+	}
+
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return res, nil
+}
@@ -0,0 +1,56 @@
+package twitch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheGetSet(t *testing.T) {
+	cache := NewMemoryCache()
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Fatal("expected miss for a key that was never set")
+	}
+
+	cache.Set("key", []byte("body"), time.Minute)
+
+	body, ok := cache.Get("key")
+	if !ok {
+		t.Fatal("expected hit after Set")
+	}
+
+	if string(body) != "body" {
+		t.Fatalf("got body %q, want %q", body, "body")
+	}
+}
+
+func TestMemoryCacheExpires(t *testing.T) {
+	c := &memoryCache{entries: make(map[string]cacheEntry)}
+
+	// Bypass the MinCacheTTL floor by writing the entry directly so the expiry can be
+	// observed without sleeping for MinCacheTTL.
+	c.entries["key"] = cacheEntry{
+		body:      []byte("body"),
+		expiresAt: time.Now().Add(-time.Second),
+	}
+
+	if _, ok := c.Get("key"); ok {
+		t.Fatal("expected miss for an expired entry")
+	}
+
+	if _, ok := c.entries["key"]; ok {
+		t.Fatal("expected expired entry to be evicted from the map")
+	}
+}
+
+func TestMemoryCacheSetEnforcesMinTTLFloor(t *testing.T) {
+	c := &memoryCache{entries: make(map[string]cacheEntry)}
+
+	before := time.Now()
+	c.Set("key", []byte("body"), time.Second)
+
+	entry := c.entries["key"]
+	if entry.expiresAt.Before(before.Add(MinCacheTTL)) {
+		t.Fatalf("expiresAt %s is before the MinCacheTTL floor %s", entry.expiresAt, before.Add(MinCacheTTL))
+	}
+}
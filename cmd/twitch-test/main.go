@@ -20,7 +20,7 @@ func main() {
 	client = twitch.NewClient(clientKey, secret, "http://localhost:8080/authorized")
 	r := chi.NewRouter()
 
-	r.Get("/", client.Authorize("openid", "user_read", "user_subscriptions", "user_follows_edit", "user_blocks_edit"))
+	r.Get("/", client.Authorize("openid", "user:read:email", "channel:read:subscriptions", "user:manage:blocked_users"))
 	r.Get("/authorized", client.HandleAuthorization(handleAccess))
 	r.Get("/user", handleGetUser)
 	r.Get("/test", handleTest)
@@ -56,10 +56,9 @@ func handleGetUser(w http.ResponseWriter, r *http.Request) {
 func handleTest(w http.ResponseWriter, r *http.Request) {
 	scope := []string{
 		"openid",
-		"user_read",
-		"user_subscriptions",
-		"user_follows_edit",
-		"user_blocks_edit",
+		"user:read:email",
+		"channel:read:subscriptions",
+		"user:manage:blocked_users",
 	}
 
 	access := twitch.NewAccess("m724mn6yvu28rx61kxdkmedomsuu75", scope)
@@ -68,17 +67,17 @@ func handleTest(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Failed to get users: %s", err)
 	}
 
-	userID := users[0].ID
 	blockID := users[1].ID
 
-	block, err := client.WithAccess(access).BlockUser(userID, blockID)
-	if err != nil {
+	if err := client.WithAccess(access).BlockUser(blockID); err != nil {
 		log.Printf("Failed to block: %s", err)
 	}
 
-	data, err := json.Marshal(&block)
+	data, err := json.Marshal(&struct {
+		Blocked string `json:"blocked"`
+	}{blockID})
 	if err != nil {
-		log.Printf("Failed to marshal json: %s")
+		log.Printf("Failed to marshal json: %s", err)
 	}
 
 	w.Write(data)
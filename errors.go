@@ -0,0 +1,65 @@
+package twitch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// An APIError carries the HTTP status and decoded error payload for a failed Twitch API call.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	URL     string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("twitch: %s (status %d, %s)", e.Message, e.Status, e.URL)
+	}
+
+	return fmt.Sprintf("twitch: request to %s failed with status %d", e.URL, e.Status)
+}
+
+// Sentinel errors for the Twitch API failures this package special-cases. When one of these
+// is returned because of a non-2xx response, the error also unwraps to the underlying
+// *APIError, so callers that need the status code or message can still get at it with
+// errors.As. When it is returned because a successful response held no matching data, there
+// is no *APIError to unwrap.
+var (
+	ErrNotSubscribed = errors.New("user is not subscribed")
+	ErrNoSubProgram  = errors.New("channel does not have a subscription program")
+	ErrNotFollowing  = errors.New("user does not follow channel")
+)
+
+// checkResponse reads res's body as Twitch's standard {"error","status","message"} envelope
+// and returns an *APIError if res wasn't a 2xx response, or nil otherwise.
+func checkResponse(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	var body struct {
+		Error   string `json:"error"`
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	}
+
+	// Best effort: a response that doesn't match the envelope still produces an APIError
+	// carrying the status code.
+	json.NewDecoder(res.Body).Decode(&body)
+
+	var reqURL string
+	if res.Request != nil && res.Request.URL != nil {
+		reqURL = res.Request.URL.String()
+	}
+
+	return &APIError{
+		Status:  res.StatusCode,
+		Code:    body.Error,
+		Message: body.Message,
+		URL:     reqURL,
+	}
+}
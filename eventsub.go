@@ -0,0 +1,405 @@
+package twitch
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	eventSubMessageTypeVerification = "webhook_callback_verification"
+	eventSubMessageTypeNotification = "notification"
+	eventSubMessageTypeRevocation   = "revocation"
+
+	// eventSubMessageWindow bounds both the replay-rejection window and the dedup cache.
+	eventSubMessageWindow = 10 * time.Minute
+)
+
+// EventSubTransport describes how a subscription's events are delivered. Only the "webhook"
+// method is supported.
+type EventSubTransport struct {
+	Method   string `json:"method"`
+	Callback string `json:"callback"`
+	Secret   string `json:"secret,omitempty"`
+}
+
+// EventSubSubscription is Twitch's representation of a registered EventSub subscription.
+type EventSubSubscription struct {
+	ID        string            `json:"id"`
+	Status    string            `json:"status"`
+	Type      string            `json:"type"`
+	Version   string            `json:"version"`
+	Condition map[string]string `json:"condition"`
+	Transport EventSubTransport `json:"transport"`
+	CreatedAt time.Time         `json:"created_at"`
+	Cost      int               `json:"cost"`
+}
+
+// StreamOnlineEvent is the payload delivered for a stream.online subscription.
+type StreamOnlineEvent struct {
+	ID                   string    `json:"id"`
+	BroadcasterUserID    string    `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string    `json:"broadcaster_user_login"`
+	BroadcasterUserName  string    `json:"broadcaster_user_name"`
+	Type                 string    `json:"type"`
+	StartedAt            time.Time `json:"started_at"`
+}
+
+// StreamOfflineEvent is the payload delivered for a stream.offline subscription.
+type StreamOfflineEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+}
+
+// ChannelFollowEvent is the payload delivered for a channel.follow subscription.
+type ChannelFollowEvent struct {
+	UserID               string    `json:"user_id"`
+	UserLogin            string    `json:"user_login"`
+	UserName             string    `json:"user_name"`
+	BroadcasterUserID    string    `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string    `json:"broadcaster_user_login"`
+	BroadcasterUserName  string    `json:"broadcaster_user_name"`
+	FollowedAt           time.Time `json:"followed_at"`
+}
+
+// ChannelSubscribeEvent is the payload delivered for a channel.subscribe subscription.
+type ChannelSubscribeEvent struct {
+	UserID               string `json:"user_id"`
+	UserLogin            string `json:"user_login"`
+	UserName             string `json:"user_name"`
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	Tier                 string `json:"tier"`
+	IsGift               bool   `json:"is_gift"`
+}
+
+// ChannelUpdateEvent is the payload delivered for a channel.update subscription.
+type ChannelUpdateEvent struct {
+	BroadcasterUserID    string `json:"broadcaster_user_id"`
+	BroadcasterUserLogin string `json:"broadcaster_user_login"`
+	BroadcasterUserName  string `json:"broadcaster_user_name"`
+	Title                string `json:"title"`
+	Language             string `json:"language"`
+	CategoryID           string `json:"category_id"`
+	CategoryName         string `json:"category_name"`
+}
+
+// An EventSubHandler is an http.Handler that verifies and dispatches Twitch EventSub webhook
+// deliveries. Register interest with the On* methods before wiring it into a mux.
+type EventSubHandler struct {
+	secret string
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+
+	onStreamOnline     []func(StreamOnlineEvent)
+	onStreamOffline    []func(StreamOfflineEvent)
+	onChannelFollow    []func(ChannelFollowEvent)
+	onChannelSubscribe []func(ChannelSubscribeEvent)
+	onChannelUpdate    []func(ChannelUpdateEvent)
+	onRevocation       func(subType, subID string)
+}
+
+// NewEventSubHandler creates an EventSubHandler that verifies deliveries against secret, the
+// same secret passed to CreateEventSubSubscription for the subscriptions it serves.
+func NewEventSubHandler(secret string) *EventSubHandler {
+	return &EventSubHandler{
+		secret: secret,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// OnStreamOnline registers fn to be called for every stream.online notification.
+func (h *EventSubHandler) OnStreamOnline(fn func(StreamOnlineEvent)) {
+	h.onStreamOnline = append(h.onStreamOnline, fn)
+}
+
+// OnStreamOffline registers fn to be called for every stream.offline notification.
+func (h *EventSubHandler) OnStreamOffline(fn func(StreamOfflineEvent)) {
+	h.onStreamOffline = append(h.onStreamOffline, fn)
+}
+
+// OnChannelFollow registers fn to be called for every channel.follow notification.
+func (h *EventSubHandler) OnChannelFollow(fn func(ChannelFollowEvent)) {
+	h.onChannelFollow = append(h.onChannelFollow, fn)
+}
+
+// OnChannelSubscribe registers fn to be called for every channel.subscribe notification.
+func (h *EventSubHandler) OnChannelSubscribe(fn func(ChannelSubscribeEvent)) {
+	h.onChannelSubscribe = append(h.onChannelSubscribe, fn)
+}
+
+// OnChannelUpdate registers fn to be called for every channel.update notification.
+func (h *EventSubHandler) OnChannelUpdate(fn func(ChannelUpdateEvent)) {
+	h.onChannelUpdate = append(h.onChannelUpdate, fn)
+}
+
+// OnRevocation registers fn to be called whenever Twitch revokes a subscription, passing
+// along the subscription's type and ID.
+func (h *EventSubHandler) OnRevocation(fn func(subType, subID string)) {
+	h.onRevocation = fn
+}
+
+// ServeHTTP implements http.Handler, verifying the delivery's signature before dispatching it
+// based on the Twitch-Eventsub-Message-Type header.
+func (h *EventSubHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	messageID := r.Header.Get("Twitch-Eventsub-Message-Id")
+	timestamp := r.Header.Get("Twitch-Eventsub-Message-Timestamp")
+	signature := r.Header.Get("Twitch-Eventsub-Message-Signature")
+
+	if !h.verifySignature(messageID, timestamp, body, signature) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	sentAt, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil || time.Since(sentAt) > eventSubMessageWindow {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if h.isDuplicate(messageID) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	switch r.Header.Get("Twitch-Eventsub-Message-Type") {
+	case eventSubMessageTypeVerification:
+		h.handleVerification(w, body)
+	case eventSubMessageTypeNotification:
+		h.handleNotification(w, body)
+	case eventSubMessageTypeRevocation:
+		h.handleRevocation(w, body)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// verifySignature recomputes the HMAC-SHA256 signature Twitch attaches to every delivery and
+// compares it in constant time against the Twitch-Eventsub-Message-Signature header.
+func (h *EventSubHandler) verifySignature(messageID, timestamp string, body []byte, signature string) bool {
+	mac := hmac.New(sha256.New, []byte(h.secret))
+	mac.Write([]byte(messageID))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// isDuplicate reports whether messageID has already been seen within eventSubMessageWindow,
+// recording it if not. Entries older than the window are evicted on every call.
+func (h *EventSubHandler) isDuplicate(messageID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for id, seenAt := range h.seen {
+		if now.Sub(seenAt) > eventSubMessageWindow {
+			delete(h.seen, id)
+		}
+	}
+
+	if _, ok := h.seen[messageID]; ok {
+		return true
+	}
+
+	h.seen[messageID] = now
+	return false
+}
+
+func (h *EventSubHandler) handleVerification(w http.ResponseWriter, body []byte) {
+	var payload struct {
+		Challenge string `json:"challenge"`
+	}
+
+	if err := json.Unmarshal(body, &payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(payload.Challenge))
+}
+
+func (h *EventSubHandler) handleNotification(w http.ResponseWriter, body []byte) {
+	var payload struct {
+		Subscription EventSubSubscription `json:"subscription"`
+		Event        json.RawMessage      `json:"event"`
+	}
+
+	if err := json.Unmarshal(body, &payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch payload.Subscription.Type {
+	case "stream.online":
+		var event StreamOnlineEvent
+		if json.Unmarshal(payload.Event, &event) == nil {
+			for _, fn := range h.onStreamOnline {
+				fn(event)
+			}
+		}
+	case "stream.offline":
+		var event StreamOfflineEvent
+		if json.Unmarshal(payload.Event, &event) == nil {
+			for _, fn := range h.onStreamOffline {
+				fn(event)
+			}
+		}
+	case "channel.follow":
+		var event ChannelFollowEvent
+		if json.Unmarshal(payload.Event, &event) == nil {
+			for _, fn := range h.onChannelFollow {
+				fn(event)
+			}
+		}
+	case "channel.subscribe":
+		var event ChannelSubscribeEvent
+		if json.Unmarshal(payload.Event, &event) == nil {
+			for _, fn := range h.onChannelSubscribe {
+				fn(event)
+			}
+		}
+	case "channel.update":
+		var event ChannelUpdateEvent
+		if json.Unmarshal(payload.Event, &event) == nil {
+			for _, fn := range h.onChannelUpdate {
+				fn(event)
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *EventSubHandler) handleRevocation(w http.ResponseWriter, body []byte) {
+	var payload struct {
+		Subscription EventSubSubscription `json:"subscription"`
+	}
+
+	if err := json.Unmarshal(body, &payload); err == nil && h.onRevocation != nil {
+		h.onRevocation(payload.Subscription.Type, payload.Subscription.ID)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// CreateEventSubSubscription registers a new webhook EventSub subscription of the given type
+// and version, scoped by condition, to be delivered to callback and signed with secret.
+func (ac AccessClient) CreateEventSubSubscription(subType, version string, condition map[string]string, callback, secret string) (EventSubSubscription, error) {
+	var subscription EventSubSubscription
+
+	payload, err := json.Marshal(&struct {
+		Type      string            `json:"type"`
+		Version   string            `json:"version"`
+		Condition map[string]string `json:"condition"`
+		Transport EventSubTransport `json:"transport"`
+	}{
+		Type:      subType,
+		Version:   version,
+		Condition: condition,
+		Transport: EventSubTransport{
+			Method:   "webhook",
+			Callback: callback,
+			Secret:   secret,
+		},
+	})
+	if err != nil {
+		return subscription, err
+	}
+
+	uri := fmt.Sprintf("%s/eventsub/subscriptions", baseURI)
+
+	res, err := ac.makePostRequest(uri, payload)
+	if err != nil {
+		return subscription, err
+	}
+
+	if err := checkResponse(res); err != nil {
+		return subscription, err
+	}
+
+	var subRes struct {
+		Data []EventSubSubscription `json:"data"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&subRes); err != nil {
+		return subscription, err
+	}
+
+	if len(subRes.Data) == 0 {
+		return subscription, fmt.Errorf("failed to create eventsub subscription for type %s", subType)
+	}
+
+	return subRes.Data[0], nil
+}
+
+// ListEventSubSubscriptions returns a Paginator over every EventSub subscription registered
+// for the client.
+func (ac AccessClient) ListEventSubSubscriptions() *Paginator[EventSubSubscription] {
+	return NewPaginator(func(cursor string) ([]EventSubSubscription, string, error) {
+		query := url.Values{}
+		if cursor != "" {
+			query.Add("after", cursor)
+		}
+
+		uri := fmt.Sprintf("%s/eventsub/subscriptions?%s", baseURI, query.Encode())
+
+		res, err := ac.makeGetRequest(uri)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if err := checkResponse(res); err != nil {
+			return nil, "", err
+		}
+
+		var subRes struct {
+			Data       []EventSubSubscription `json:"data"`
+			Pagination struct {
+				Cursor string `json:"cursor"`
+			} `json:"pagination"`
+		}
+
+		if err := json.NewDecoder(res.Body).Decode(&subRes); err != nil {
+			return nil, "", err
+		}
+
+		return subRes.Data, subRes.Pagination.Cursor, nil
+	})
+}
+
+// DeleteEventSubSubscription removes the EventSub subscription with the given ID.
+func (ac AccessClient) DeleteEventSubSubscription(id string) error {
+	uri := fmt.Sprintf("%s/eventsub/subscriptions?id=%s", baseURI, id)
+
+	res, err := ac.makeDeleteRequest(uri)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusNoContent {
+		return checkResponse(res)
+	}
+
+	return nil
+}
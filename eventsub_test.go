@@ -0,0 +1,122 @@
+package twitch
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func sign(secret, messageID, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(messageID))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignatureAcceptsValidSignature(t *testing.T) {
+	h := NewEventSubHandler("shh")
+	body := []byte(`{"hello":"world"}`)
+	sig := sign("shh", "msg-1", "2026-07-25T00:00:00Z", body)
+
+	if !h.verifySignature("msg-1", "2026-07-25T00:00:00Z", body, sig) {
+		t.Fatal("expected a correctly signed delivery to verify")
+	}
+}
+
+func TestVerifySignatureRejectsWrongSecret(t *testing.T) {
+	h := NewEventSubHandler("shh")
+	body := []byte(`{"hello":"world"}`)
+	sig := sign("wrong", "msg-1", "2026-07-25T00:00:00Z", body)
+
+	if h.verifySignature("msg-1", "2026-07-25T00:00:00Z", body, sig) {
+		t.Fatal("expected a delivery signed with the wrong secret to be rejected")
+	}
+}
+
+func TestVerifySignatureRejectsTamperedBody(t *testing.T) {
+	h := NewEventSubHandler("shh")
+	sig := sign("shh", "msg-1", "2026-07-25T00:00:00Z", []byte(`{"hello":"world"}`))
+
+	if h.verifySignature("msg-1", "2026-07-25T00:00:00Z", []byte(`{"hello":"mallory"}`), sig) {
+		t.Fatal("expected a delivery with a tampered body to be rejected")
+	}
+}
+
+func TestIsDuplicateDetectsRepeatedMessageID(t *testing.T) {
+	h := NewEventSubHandler("shh")
+
+	if h.isDuplicate("msg-1") {
+		t.Fatal("expected the first sighting of a message ID to not be a duplicate")
+	}
+
+	if !h.isDuplicate("msg-1") {
+		t.Fatal("expected the second sighting of the same message ID to be a duplicate")
+	}
+}
+
+func TestIsDuplicateEvictsEntriesOutsideWindow(t *testing.T) {
+	h := NewEventSubHandler("shh")
+
+	h.mu.Lock()
+	h.seen["msg-1"] = time.Now().Add(-eventSubMessageWindow - time.Second)
+	h.mu.Unlock()
+
+	if h.isDuplicate("msg-1") {
+		t.Fatal("expected a message ID seen outside the window to not count as a duplicate")
+	}
+
+	h.mu.Lock()
+	_, stillPresent := h.seen["msg-1"]
+	h.mu.Unlock()
+
+	if !stillPresent {
+		t.Fatal("expected isDuplicate to re-record the message ID as freshly seen")
+	}
+}
+
+func postEventSub(h *EventSubHandler, messageType, timestamp string, body []byte) *httptest.ResponseRecorder {
+	sig := sign(h.secret, "msg-1", timestamp, body)
+
+	req := httptest.NewRequest(http.MethodPost, "/eventsub", bytes.NewReader(body))
+	req.Header.Set("Twitch-Eventsub-Message-Id", "msg-1")
+	req.Header.Set("Twitch-Eventsub-Message-Timestamp", timestamp)
+	req.Header.Set("Twitch-Eventsub-Message-Signature", sig)
+	req.Header.Set("Twitch-Eventsub-Message-Type", messageType)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServeHTTPRejectsDeliveryOutsideReplayWindow(t *testing.T) {
+	h := NewEventSubHandler("shh")
+	timestamp := time.Now().Add(-eventSubMessageWindow - time.Minute).Format(time.RFC3339)
+
+	rec := postEventSub(h, eventSubMessageTypeVerification, timestamp, []byte(`{"challenge":"abc"}`))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d for a delivery outside the replay window", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestServeHTTPAcceptsDeliveryWithinReplayWindow(t *testing.T) {
+	h := NewEventSubHandler("shh")
+	timestamp := time.Now().Format(time.RFC3339)
+
+	rec := postEventSub(h, eventSubMessageTypeVerification, timestamp, []byte(`{"challenge":"abc"}`))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d for a fresh delivery", rec.Code, http.StatusOK)
+	}
+
+	if rec.Body.String() != "abc" {
+		t.Fatalf("got body %q, want the echoed challenge %q", rec.Body.String(), "abc")
+	}
+}
@@ -0,0 +1,77 @@
+package twitch
+
+import (
+	"context"
+	"fmt"
+)
+
+// Paginator walks a cursor-paginated Helix list endpoint one page at a time.
+type Paginator[T any] struct {
+	fetch  func(cursor string) ([]T, string, error)
+	cursor string
+	done   bool
+}
+
+// NewPaginator creates a Paginator that calls fetch for each page, passing the cursor
+// returned by the previous call (empty for the first page) and receiving back the page's
+// items along with the next cursor ("" once exhausted).
+func NewPaginator[T any](fetch func(cursor string) ([]T, string, error)) *Paginator[T] {
+	return &Paginator[T]{fetch: fetch}
+}
+
+// HasMore reports whether there's another page left to fetch.
+func (p *Paginator[T]) HasMore() bool {
+	return !p.done
+}
+
+// Cursor returns the cursor that will be used by the next call to Next.
+func (p *Paginator[T]) Cursor() string {
+	return p.cursor
+}
+
+// Next fetches and returns the next page of results.
+func (p *Paginator[T]) Next() ([]T, error) {
+	if p.done {
+		return nil, fmt.Errorf("paginator has no more pages")
+	}
+
+	items, cursor, err := p.fetch(p.cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	p.cursor = cursor
+	if cursor == "" {
+		p.done = true
+	}
+
+	return items, nil
+}
+
+// Collect drains p into a single slice, stopping once max items have been collected or p is
+// exhausted, whichever comes first. A non-positive max drains p completely. ctx is checked
+// between pages so a long drain can be canceled.
+func Collect[T any](ctx context.Context, p *Paginator[T], max int) ([]T, error) {
+	var all []T
+
+	for p.HasMore() {
+		select {
+		case <-ctx.Done():
+			return all, ctx.Err()
+		default:
+		}
+
+		items, err := p.Next()
+		if err != nil {
+			return all, err
+		}
+
+		all = append(all, items...)
+
+		if max > 0 && len(all) >= max {
+			return all[:max], nil
+		}
+	}
+
+	return all, nil
+}
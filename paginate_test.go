@@ -0,0 +1,102 @@
+package twitch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestPaginatorWalksAllPages(t *testing.T) {
+	pages := [][]int{{1, 2}, {3, 4}, {5}}
+
+	p := NewPaginator(func(cursor string) ([]int, string, error) {
+		i := 0
+		if cursor != "" {
+			fmt.Sscanf(cursor, "%d", &i)
+		}
+
+		items := pages[i]
+		next := ""
+		if i+1 < len(pages) {
+			next = fmt.Sprintf("%d", i+1)
+		}
+
+		return items, next, nil
+	})
+
+	var got []int
+	for p.HasMore() {
+		items, err := p.Next()
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+
+		got = append(got, items...)
+	}
+
+	if len(got) != 5 {
+		t.Fatalf("got %d items, want 5", len(got))
+	}
+}
+
+func TestCollectDrainsAllPages(t *testing.T) {
+	pages := [][]int{{1, 2}, {3, 4}, {5}}
+	i := 0
+
+	p := NewPaginator(func(cursor string) ([]int, string, error) {
+		items := pages[i]
+		i++
+
+		next := ""
+		if i < len(pages) {
+			next = "more"
+		}
+
+		return items, next, nil
+	})
+
+	got, err := Collect(context.Background(), p, 0)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	if len(got) != 5 {
+		t.Fatalf("got %d items, want 5", len(got))
+	}
+}
+
+func TestCollectStopsAtMax(t *testing.T) {
+	i := 0
+
+	p := NewPaginator(func(cursor string) ([]int, string, error) {
+		i++
+		return []int{i, i}, "more", nil
+	})
+
+	got, err := Collect(context.Background(), p, 3)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("got %d items, want 3", len(got))
+	}
+}
+
+func TestNextAfterExhaustionErrors(t *testing.T) {
+	p := NewPaginator(func(cursor string) ([]int, string, error) {
+		return []int{1}, "", nil
+	})
+
+	if _, err := p.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+
+	if p.HasMore() {
+		t.Fatal("expected HasMore to be false after the final page")
+	}
+
+	if _, err := p.Next(); err == nil {
+		t.Fatal("expected an error calling Next on an exhausted paginator")
+	}
+}
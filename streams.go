@@ -0,0 +1,175 @@
+package twitch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// A Category represents a Twitch game/category, as returned by the games endpoints.
+type Category struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	BoxArtURL string `json:"box_art_url"`
+}
+
+// StreamInfo models a single live stream, as returned by /helix/streams.
+type StreamInfo struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"user_id"`
+	UserLogin    string    `json:"user_login"`
+	UserName     string    `json:"user_name"`
+	GameID       string    `json:"game_id"`
+	GameName     string    `json:"game_name"`
+	Type         string    `json:"type"`
+	Title        string    `json:"title"`
+	ViewerCount  uint      `json:"viewer_count"`
+	StartedAt    time.Time `json:"started_at"`
+	Language     string    `json:"language"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+}
+
+// StreamsOptions filters the results of GetStreams. Zero-value fields are omitted from the
+// request.
+type StreamsOptions struct {
+	UserIDs    []string
+	UserLogins []string
+	GameIDs    []string
+	Language   string
+	First      int
+	Cursor     string
+}
+
+// GetStreams retrieves currently live streams matching opts.
+func (c Client) GetStreams(opts StreamsOptions) ([]StreamInfo, error) {
+	query := url.Values{}
+	for _, id := range opts.UserIDs {
+		query.Add("user_id", id)
+	}
+	for _, login := range opts.UserLogins {
+		query.Add("user_login", login)
+	}
+	for _, gameID := range opts.GameIDs {
+		query.Add("game_id", gameID)
+	}
+	if opts.Language != "" {
+		query.Add("language", opts.Language)
+	}
+	if opts.First > 0 {
+		query.Add("first", strconv.Itoa(opts.First))
+	}
+	if opts.Cursor != "" {
+		query.Add("after", opts.Cursor)
+	}
+
+	uri := fmt.Sprintf("%s/streams?%s", baseURI, query.Encode())
+
+	res, err := c.makeGetRequest(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkResponse(res); err != nil {
+		return nil, err
+	}
+
+	var streamRes struct {
+		Data []StreamInfo `json:"data"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&streamRes); err != nil {
+		return nil, err
+	}
+
+	return streamRes.Data, nil
+}
+
+// ScheduleSegment is a single entry in a channel's stream schedule.
+type ScheduleSegment struct {
+	ID            string     `json:"id"`
+	StartTime     time.Time  `json:"start_time"`
+	EndTime       time.Time  `json:"end_time"`
+	Title         string     `json:"title"`
+	CanceledUntil *time.Time `json:"canceled_until"`
+	Category      Category   `json:"category"`
+	IsRecurring   bool       `json:"is_recurring"`
+}
+
+// Schedule is a channel's full stream schedule, including any upcoming vacation window.
+type Schedule struct {
+	Segments []ScheduleSegment `json:"segments"`
+	Vacation *struct {
+		StartTime time.Time `json:"start_time"`
+		EndTime   time.Time `json:"end_time"`
+	} `json:"vacation"`
+}
+
+// GetStreamSchedule retrieves the stream schedule for the given broadcaster.
+func (c Client) GetStreamSchedule(broadcasterID string) (Schedule, error) {
+	var schedule Schedule
+
+	uri := fmt.Sprintf("%s/schedule?broadcaster_id=%s", baseURI, broadcasterID)
+
+	res, err := c.makeGetRequest(uri)
+	if err != nil {
+		return schedule, err
+	}
+
+	if err := checkResponse(res); err != nil {
+		return schedule, err
+	}
+
+	var scheduleRes struct {
+		Data Schedule `json:"data"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&scheduleRes); err != nil {
+		return schedule, err
+	}
+
+	return scheduleRes.Data, nil
+}
+
+// GetCategoryByName looks up a category (game) by its exact name.
+func (c Client) GetCategoryByName(name string) (Category, error) {
+	return c.getCategory("name", name)
+}
+
+// GetCategoryByID looks up a category (game) by its ID.
+func (c Client) GetCategoryByID(id string) (Category, error) {
+	return c.getCategory("id", id)
+}
+
+func (c Client) getCategory(param, value string) (Category, error) {
+	var category Category
+
+	query := url.Values{}
+	query.Add(param, value)
+
+	uri := fmt.Sprintf("%s/games?%s", baseURI, query.Encode())
+
+	res, err := c.makeGetRequest(uri)
+	if err != nil {
+		return category, err
+	}
+
+	if err := checkResponse(res); err != nil {
+		return category, err
+	}
+
+	var categoryRes struct {
+		Data []Category `json:"data"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&categoryRes); err != nil {
+		return category, err
+	}
+
+	if len(categoryRes.Data) == 0 {
+		return category, fmt.Errorf("no category found for %s %s", param, value)
+	}
+
+	return categoryRes.Data[0], nil
+}
@@ -0,0 +1,225 @@
+package twitch
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultRequestTimeout bounds how long a single attempt at a request is allowed to take
+// before its context is canceled. Each retry gets its own fresh budget.
+const DefaultRequestTimeout = 2 * time.Second
+
+// RetryPolicy configures how the rate-limit-aware transport retries failed requests.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// DefaultRetryPolicy retries up to 5 times with exponential backoff starting at 250ms.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	BaseDelay:  250 * time.Millisecond,
+	MaxDelay:   10 * time.Second,
+}
+
+var retryableStatus = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// transport is the package-level rate-limit-aware RoundTripper backing httpClient.
+var transport *rateLimitTransport
+
+// rateLimitTransport is an http.RoundTripper that tracks Twitch's Ratelimit-* response
+// headers, blocking new requests once the limit is exhausted until the window resets, and
+// retries retryable failures with exponential backoff plus jitter.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	policy  RetryPolicy
+	timeout time.Duration
+
+	mu        sync.Mutex
+	limit     int
+	remaining int
+	hasLimit  bool
+	resetAt   time.Time
+}
+
+func newRateLimitTransport() *rateLimitTransport {
+	return &rateLimitTransport{
+		next:    http.DefaultTransport,
+		policy:  DefaultRetryPolicy,
+		timeout: DefaultRequestTimeout,
+	}
+}
+
+// getPolicy and getTimeout return the transport's current retry policy/timeout under t.mu, so
+// a concurrent SetRetryPolicy/SetHTTPClient call while requests are in flight can't race with
+// RoundTrip reading them.
+func (t *rateLimitTransport) getPolicy() RetryPolicy {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.policy
+}
+
+func (t *rateLimitTransport) getTimeout() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.timeout
+}
+
+func (t *rateLimitTransport) setPolicy(policy RetryPolicy) {
+	t.mu.Lock()
+	t.policy = policy
+	t.mu.Unlock()
+}
+
+func (t *rateLimitTransport) setTimeout(timeout time.Duration) {
+	t.mu.Lock()
+	t.timeout = timeout
+	t.mu.Unlock()
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.waitForLimit()
+
+	policy := t.getPolicy()
+	timeout := t.getTimeout()
+
+	var res *http.Response
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, berr := req.GetBody()
+			if berr != nil {
+				return nil, berr
+			}
+
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		// The context is canceled once timeout elapses rather than when RoundTrip returns, so
+		// the returned response's body stays readable for the rest of the attempt's budget.
+		ctx, cancel := context.WithCancel(attemptReq.Context())
+		timer := time.AfterFunc(timeout, cancel)
+		attemptReq = attemptReq.WithContext(ctx)
+
+		res, err = t.next.RoundTrip(attemptReq)
+
+		if err == nil {
+			t.recordLimit(res)
+		}
+
+		retry := err != nil || (res != nil && retryableStatus[res.StatusCode])
+		if !retry || attempt >= policy.MaxRetries {
+			return res, err
+		}
+
+		if res != nil {
+			res.Body.Close()
+		}
+		timer.Stop()
+		cancel()
+
+		select {
+		case <-time.After(t.backoff(policy, attempt)):
+		case <-req.Context().Done():
+			return res, req.Context().Err()
+		}
+	}
+}
+
+// waitForLimit blocks until the rate limit window resets if the last observed response
+// reported no remaining requests.
+func (t *rateLimitTransport) waitForLimit() {
+	t.mu.Lock()
+	var wait time.Duration
+	if t.hasLimit && t.remaining <= 0 {
+		if d := time.Until(t.resetAt); d > 0 {
+			wait = d
+		}
+	}
+	t.mu.Unlock()
+
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+// recordLimit parses Twitch's Ratelimit-Limit, Ratelimit-Remaining, and Ratelimit-Reset
+// headers off of res, ignoring responses that don't carry them.
+func (t *rateLimitTransport) recordLimit(res *http.Response) {
+	remaining := res.Header.Get("Ratelimit-Remaining")
+	reset := res.Header.Get("Ratelimit-Reset")
+	if remaining == "" || reset == "" {
+		return
+	}
+
+	r, err := strconv.Atoi(remaining)
+	if err != nil {
+		return
+	}
+
+	epoch, err := strconv.ParseInt(reset, 10, 64)
+	if err != nil {
+		return
+	}
+
+	limit, _ := strconv.Atoi(res.Header.Get("Ratelimit-Limit"))
+
+	t.mu.Lock()
+	t.limit = limit
+	t.remaining = r
+	t.resetAt = time.Unix(epoch, 0)
+	t.hasLimit = true
+	t.mu.Unlock()
+}
+
+// backoff returns the delay before the given retry attempt under policy: BaseDelay doubled
+// once per attempt and capped at MaxDelay, of which half is fixed and the other half is
+// random jitter, so the result lies between half and all of the capped delay.
+func (t *rateLimitTransport) backoff(policy RetryPolicy, attempt int) time.Duration {
+	delay := policy.BaseDelay << uint(attempt)
+	if delay <= 0 || delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return delay/2 + jitter
+}
+
+// SetHTTPClient overrides the package-level http.Client used for every Twitch API request.
+// Its existing Transport (if any) is preserved as the underlying round tripper beneath the
+// package's rate-limit-aware retry logic.
+func SetHTTPClient(client *http.Client) {
+	t := newRateLimitTransport()
+	t.policy = transport.getPolicy()
+	t.timeout = transport.getTimeout()
+	if client.Transport != nil {
+		t.next = client.Transport
+	}
+
+	client.Transport = t
+	transport = t
+	httpClient = client
+}
+
+// SetRetryPolicy overrides the retry/backoff behavior used by every request made through the
+// package-level httpClient.
+func SetRetryPolicy(policy RetryPolicy) {
+	transport.setPolicy(policy)
+}
@@ -0,0 +1,241 @@
+package twitch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoffGrowsExponentiallyAndCapsAtMaxDelay(t *testing.T) {
+	rt := newRateLimitTransport()
+	policy := RetryPolicy{
+		MaxRetries: 10,
+		BaseDelay:  100 * time.Millisecond,
+		MaxDelay:   1 * time.Second,
+	}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		delay := rt.backoff(policy, attempt)
+		if delay <= 0 {
+			t.Fatalf("attempt %d: backoff returned non-positive delay %s", attempt, delay)
+		}
+
+		if delay > policy.MaxDelay {
+			t.Fatalf("attempt %d: backoff %s exceeded MaxDelay %s", attempt, delay, policy.MaxDelay)
+		}
+	}
+}
+
+func TestBackoffWithoutJitterFloor(t *testing.T) {
+	rt := newRateLimitTransport()
+	policy := RetryPolicy{
+		MaxRetries: 5,
+		BaseDelay:  200 * time.Millisecond,
+		MaxDelay:   10 * time.Second,
+	}
+
+	// backoff returns delay/2 plus up to delay/2 of jitter, so it should never fall below
+	// half of the unjittered exponential delay for the given attempt.
+	for attempt := 0; attempt < 5; attempt++ {
+		want := policy.BaseDelay << uint(attempt)
+		if want > policy.MaxDelay {
+			want = policy.MaxDelay
+		}
+
+		min := want / 2
+		delay := rt.backoff(policy, attempt)
+		if delay < min {
+			t.Fatalf("attempt %d: backoff %s below expected floor %s", attempt, delay, min)
+		}
+	}
+}
+
+// fakeRoundTripper counts calls and returns canned, successive responses/errors so RoundTrip's
+// retry loop can be exercised without a real network dependency.
+type fakeRoundTripper struct {
+	calls     int32
+	responses []*http.Response
+}
+
+func (f *fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := atomic.AddInt32(&f.calls, 1) - 1
+	if int(i) >= len(f.responses) {
+		i = int32(len(f.responses) - 1)
+	}
+
+	return f.responses[i], nil
+}
+
+func newFakeResponse(status int) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Body:       http.NoBody,
+		Header:     make(http.Header),
+	}
+}
+
+func TestRoundTripRetriesRetryableStatusUntilSuccess(t *testing.T) {
+	fake := &fakeRoundTripper{
+		responses: []*http.Response{
+			newFakeResponse(http.StatusServiceUnavailable),
+			newFakeResponse(http.StatusServiceUnavailable),
+			newFakeResponse(http.StatusOK),
+		},
+	}
+
+	rt := &rateLimitTransport{
+		next:    fake,
+		policy:  RetryPolicy{MaxRetries: 5, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+		timeout: time.Second,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	res, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", res.StatusCode, http.StatusOK)
+	}
+
+	if got := atomic.LoadInt32(&fake.calls); got != 3 {
+		t.Fatalf("got %d calls to the underlying transport, want 3", got)
+	}
+}
+
+func TestRoundTripGivesUpAfterMaxRetries(t *testing.T) {
+	fake := &fakeRoundTripper{
+		responses: []*http.Response{newFakeResponse(http.StatusServiceUnavailable)},
+	}
+
+	rt := &rateLimitTransport{
+		next:    fake,
+		policy:  RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond},
+		timeout: time.Second,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	res, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("got status %d, want %d", res.StatusCode, http.StatusServiceUnavailable)
+	}
+
+	// MaxRetries: 2 means the first attempt plus two retries, for three calls total.
+	if got := atomic.LoadInt32(&fake.calls); got != 3 {
+		t.Fatalf("got %d calls to the underlying transport, want 3", got)
+	}
+}
+
+func TestRecordLimitParsesHeaders(t *testing.T) {
+	rt := newRateLimitTransport()
+
+	res := newFakeResponse(http.StatusOK)
+	res.Header.Set("Ratelimit-Limit", "800")
+	res.Header.Set("Ratelimit-Remaining", "799")
+	res.Header.Set("Ratelimit-Reset", "1234567890")
+
+	rt.recordLimit(res)
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if !rt.hasLimit {
+		t.Fatal("expected hasLimit to be set after a response carrying rate limit headers")
+	}
+
+	if rt.limit != 800 || rt.remaining != 799 {
+		t.Fatalf("got limit=%d remaining=%d, want limit=800 remaining=799", rt.limit, rt.remaining)
+	}
+
+	if !rt.resetAt.Equal(time.Unix(1234567890, 0)) {
+		t.Fatalf("got resetAt %s, want %s", rt.resetAt, time.Unix(1234567890, 0))
+	}
+}
+
+func TestRecordLimitIgnoresResponsesWithoutHeaders(t *testing.T) {
+	rt := newRateLimitTransport()
+
+	rt.recordLimit(newFakeResponse(http.StatusOK))
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.hasLimit {
+		t.Fatal("expected hasLimit to remain false for a response without rate limit headers")
+	}
+}
+
+func TestWaitForLimitBlocksUntilReset(t *testing.T) {
+	rt := newRateLimitTransport()
+
+	rt.mu.Lock()
+	rt.hasLimit = true
+	rt.remaining = 0
+	rt.resetAt = time.Now().Add(30 * time.Millisecond)
+	rt.mu.Unlock()
+
+	start := time.Now()
+	rt.waitForLimit()
+	elapsed := time.Since(start)
+
+	if elapsed < 20*time.Millisecond {
+		t.Fatalf("waitForLimit returned after %s, expected to block until the reset window passed", elapsed)
+	}
+}
+
+func TestWaitForLimitDoesNotBlockWhenRequestsRemain(t *testing.T) {
+	rt := newRateLimitTransport()
+
+	rt.mu.Lock()
+	rt.hasLimit = true
+	rt.remaining = 10
+	rt.resetAt = time.Now().Add(time.Hour)
+	rt.mu.Unlock()
+
+	start := time.Now()
+	rt.waitForLimit()
+
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Fatalf("waitForLimit blocked for %s despite remaining requests", elapsed)
+	}
+}
+
+func TestPolicyAndTimeoutAccessorsAreRaceFree(t *testing.T) {
+	// Exercises the setPolicy/setTimeout accessors concurrently with RoundTrip's
+	// getPolicy/getTimeout reads the
+	// way `go test -race` would catch a missing lock around rateLimitTransport's policy/timeout
+	// fields.
+	fake := &fakeRoundTripper{responses: []*http.Response{newFakeResponse(http.StatusOK)}}
+	rt := &rateLimitTransport{
+		next:    fake,
+		policy:  DefaultRetryPolicy,
+		timeout: DefaultRequestTimeout,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			rt.setPolicy(RetryPolicy{MaxRetries: i % 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+			rt.setTimeout(time.Millisecond)
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Fatalf("RoundTrip: %v", err)
+		}
+	}
+
+	<-done
+}
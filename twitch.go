@@ -2,20 +2,44 @@ package twitch
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Package level settings.
 var baseURI string
+var authURI string
 var httpClient *http.Client
 
 func init() {
-	baseURI = "https://api.twitch.tv/kraken"
-	httpClient = http.DefaultClient
+	baseURI = "https://api.twitch.tv/helix"
+	authURI = "https://id.twitch.tv/oauth2"
+
+	transport = newRateLimitTransport()
+	httpClient = &http.Client{Transport: transport}
+}
+
+// tokenResponse models the token envelope returned from every id.twitch.tv/oauth2/token grant,
+// regardless of which grant_type produced it.
+type tokenResponse struct {
+	AccessToken  string   `json:"access_token"`
+	RefreshToken string   `json:"refresh_token"`
+	ExpiresIn    int      `json:"expires_in"`
+	Scope        []string `json:"scope"`
+}
+
+// appToken caches the App Access Token obtained via the client-credentials grant so it's
+// shared and refreshed across every copy of the Client that produced it.
+type appToken struct {
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
 }
 
 // A Client contains all of the fields necessary for making requests to the Twitch API.
@@ -23,18 +47,56 @@ type Client struct {
 	clientID    string
 	secret      string
 	redirectURI string
+
+	appToken *appToken
+	cache    APICache
+	noCache  bool
 }
 
-// An Access holds an access token along with the authorization scope associated.
+// An Access holds a user's OAuth2 access token along with the refresh token and expiry needed
+// to keep it valid, plus the authorization scope associated with it.
 type Access struct {
-	Token string   `json:"access_token"`
-	Scope []string `json:"scope"`
+	Token     string    `json:"access_token"`
+	Refresh   string    `json:"refresh_token"`
+	Scope     []string  `json:"scope"`
+	ExpiresAt time.Time `json:"-"`
+}
+
+// TokenUpdateHook is invoked whenever an AccessClient transparently rotates its user's
+// access/refresh tokens, so callers can persist the new values.
+type TokenUpdateHook func(access, refresh string, expiry time.Time) error
+
+// accessToken caches an AccessClient's user Access behind a shared pointer and mutex, the same
+// way appToken shares a Client's App Access Token: AccessClient is handed around by value, so
+// without this indirection a token rotated on one copy (e.g. during a 401 refresh) would never
+// be visible to any other copy of the same AccessClient the caller is holding.
+type accessToken struct {
+	mu     sync.Mutex
+	access Access
 }
 
 // An AccessClient wraps a twitch client with an Access struct.
 type AccessClient struct {
-	access Access
-	client Client
+	access  *accessToken
+	client  Client
+	hook    TokenUpdateHook
+	noCache bool
+}
+
+// getAccess returns the AccessClient's current Access, reflecting any refresh performed by
+// another copy of the same AccessClient.
+func (ac AccessClient) getAccess() Access {
+	ac.access.mu.Lock()
+	defer ac.access.mu.Unlock()
+
+	return ac.access.access
+}
+
+// setAccess updates the Access shared by every copy of this AccessClient.
+func (ac AccessClient) setAccess(access Access) {
+	ac.access.mu.Lock()
+	ac.access.access = access
+	ac.access.mu.Unlock()
 }
 
 // NewClient creates a new Client for communicating with the Twitch API.
@@ -43,9 +105,18 @@ func NewClient(clientID, secret, redirectURI string) Client {
 		clientID:    clientID,
 		secret:      secret,
 		redirectURI: redirectURI,
+		appToken:    &appToken{},
+		cache:       NewMemoryCache(),
 	}
 }
 
+// NewHelixClient creates a new Client configured to authenticate against Twitch's Helix API.
+// It returns exactly what NewClient returns, so existing callers of NewClient keep working
+// unchanged.
+func NewHelixClient(clientID, clientSecret, redirectURI string) Client {
+	return NewClient(clientID, clientSecret, redirectURI)
+}
+
 // NewAccess creates an Access struct from an existing token/scope combination.
 func NewAccess(token string, scope []string) Access {
 	return Access{
@@ -69,11 +140,33 @@ func (c Client) RedirectURI() string {
 // WithAccess wraps the Client with an Access struct.
 func (c Client) WithAccess(access Access) AccessClient {
 	return AccessClient{
-		access: access,
-		client: c,
+		access:  &accessToken{access: access},
+		client:  c,
+		noCache: c.noCache,
 	}
 }
 
+// WithTokenUpdateHook attaches a hook that's called whenever the AccessClient rotates its
+// user token via a refresh_token grant, so the caller can persist the new values.
+func (ac AccessClient) WithTokenUpdateHook(hook TokenUpdateHook) AccessClient {
+	ac.hook = hook
+	return ac
+}
+
+// WithNoCache returns a copy of the Client that bypasses the response cache, always hitting
+// the API directly.
+func (c Client) WithNoCache() Client {
+	c.noCache = true
+	return c
+}
+
+// WithNoCache returns a copy of the AccessClient that bypasses the response cache, always
+// hitting the API directly.
+func (ac AccessClient) WithNoCache() AccessClient {
+	ac.noCache = true
+	return ac
+}
+
 // Authorize is an http handler that can be used to prompt a user for Authorization.
 func (c Client) Authorize(scope ...string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -94,7 +187,7 @@ func (c Client) HandleAuthorization(handleAccess func(access Access, err error))
 
 func (c Client) getAccessToken(authCode string) (Access, error) {
 	var access Access
-	uri, _ := url.Parse(fmt.Sprintf("%s/oauth2/token", baseURI))
+	uri, _ := url.Parse(fmt.Sprintf("%s/token", authURI))
 	query := url.Values{}
 
 	query.Add("client_id", c.ClientID())
@@ -114,16 +207,156 @@ func (c Client) getAccessToken(authCode string) (Access, error) {
 	if err != nil {
 		return access, err
 	}
+	defer res.Body.Close()
+
+	var tokenRes tokenResponse
+	if err := json.NewDecoder(res.Body).Decode(&tokenRes); err != nil {
+		return access, err
+	}
+
+	access.Token = tokenRes.AccessToken
+	access.Refresh = tokenRes.RefreshToken
+	access.Scope = tokenRes.Scope
+	access.ExpiresAt = time.Now().Add(time.Duration(tokenRes.ExpiresIn) * time.Second)
+
+	return access, nil
+}
+
+// getAppAccessToken returns a cached App Access Token obtained via the client-credentials
+// grant, fetching and caching a new one if the current one is missing or expired.
+func (c Client) getAppAccessToken() (string, error) {
+	c.appToken.mu.Lock()
+	defer c.appToken.mu.Unlock()
+
+	if c.appToken.token != "" && time.Now().Before(c.appToken.expiresAt) {
+		return c.appToken.token, nil
+	}
+
+	uri, _ := url.Parse(fmt.Sprintf("%s/token", authURI))
+	query := url.Values{}
+
+	query.Add("client_id", c.ClientID())
+	query.Add("client_secret", c.Secret())
+	query.Add("grant_type", "client_credentials")
+
+	uri.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("POST", uri.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var tokenRes tokenResponse
+	if err := json.NewDecoder(res.Body).Decode(&tokenRes); err != nil {
+		return "", err
+	}
+
+	c.appToken.token = tokenRes.AccessToken
+	c.appToken.expiresAt = time.Now().Add(time.Duration(tokenRes.ExpiresIn) * time.Second)
+
+	return c.appToken.token, nil
+}
+
+// refreshUserToken exchanges the AccessClient's refresh token for a new access/refresh token
+// pair via the refresh_token grant.
+func (ac AccessClient) refreshUserToken() (Access, error) {
+	var access Access
+	uri, _ := url.Parse(fmt.Sprintf("%s/token", authURI))
+	query := url.Values{}
+
+	query.Add("client_id", ac.client.ClientID())
+	query.Add("client_secret", ac.client.Secret())
+	query.Add("grant_type", "refresh_token")
+	query.Add("refresh_token", ac.getAccess().Refresh)
+
+	uri.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("POST", uri.String(), nil)
+	if err != nil {
+		return access, err
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return access, err
+	}
+	defer res.Body.Close()
 
-	if err := json.NewDecoder(res.Body).Decode(&access); err != nil {
+	var tokenRes tokenResponse
+	if err := json.NewDecoder(res.Body).Decode(&tokenRes); err != nil {
 		return access, err
 	}
 
+	access.Token = tokenRes.AccessToken
+	access.Refresh = tokenRes.RefreshToken
+	access.Scope = tokenRes.Scope
+	access.ExpiresAt = time.Now().Add(time.Duration(tokenRes.ExpiresIn) * time.Second)
+
 	return access, nil
 }
 
+// ValidateToken checks the AccessClient's user token against Twitch's validate endpoint,
+// returning an error if the token has been revoked or is otherwise no longer valid.
+func (ac AccessClient) ValidateToken() error {
+	uri := fmt.Sprintf("%s/validate", authURI)
+
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("OAuth %s", ac.getAccess().Token))
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("token is no longer valid, status: %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// StartTokenValidation periodically calls ValidateToken on the given interval (defaulting to
+// once an hour), invoking onInvalid whenever validation fails so callers can react to token
+// revocation. Call the returned context.CancelFunc to stop validating.
+func (ac AccessClient) StartTokenValidation(interval time.Duration, onInvalid func(error)) context.CancelFunc {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := ac.ValidateToken(); err != nil && onInvalid != nil {
+					onInvalid(err)
+				}
+			}
+		}
+	}()
+
+	return cancel
+}
+
 func (c Client) getAuthorizeURI(scope []string) *url.URL {
-	uri, _ := url.Parse(fmt.Sprintf("%s/oauth2/authorize", baseURI))
+	uri, _ := url.Parse(fmt.Sprintf("%s/authorize", authURI))
 	queryString := url.Values{}
 
 	queryString.Add("client_id", c.ClientID())
@@ -136,38 +369,106 @@ func (c Client) getAuthorizeURI(scope []string) *url.URL {
 }
 
 func (c Client) makeGetRequest(uri string) (*http.Response, error) {
+	key := uri + "|" + c.clientID
+
+	if !c.noCache {
+		if body, ok := c.cache.Get(key); ok {
+			return cachedResponse(body), nil
+		}
+	}
+
+	token, err := c.getAppAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
 	req, err := http.NewRequest("GET", uri, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Client-ID", c.ClientID())
-	req.Header.Set("Accept", "application/vnd.twitchtv.v5+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("Client-Id", c.ClientID())
 
-	return httpClient.Do(req)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.noCache {
+		return res, nil
+	}
+
+	return cacheResponse(res, c.cache, key)
 }
 
 func (ac AccessClient) makeGetRequest(uri string) (*http.Response, error) {
+	access := ac.getAccess()
+	key := uri + "|" + access.Token
+
+	if !ac.noCache {
+		if body, ok := ac.client.cache.Get(key); ok {
+			return cachedResponse(body), nil
+		}
+	}
+
 	req, err := http.NewRequest("GET", uri, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("OAuth %s", ac.access.Token))
-	req.Header.Set("Client-ID", ac.client.ClientID())
-	req.Header.Set("Accept", "application/vnd.twitchtv.v5+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", access.Token))
+	req.Header.Set("Client-Id", ac.client.ClientID())
 
-	return httpClient.Do(req)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusUnauthorized && access.Refresh != "" {
+		refreshed, err := ac.refreshUserToken()
+		if err != nil {
+			return res, err
+		}
+
+		if ac.hook != nil {
+			if err := ac.hook(refreshed.Token, refreshed.Refresh, refreshed.ExpiresAt); err != nil {
+				return res, err
+			}
+		}
+
+		ac.setAccess(refreshed)
+		access = refreshed
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", access.Token))
+		key = uri + "|" + access.Token
+
+		res.Body.Close()
+		if res, err = httpClient.Do(req); err != nil {
+			return res, err
+		}
+	}
+
+	if ac.noCache {
+		return res, nil
+	}
+
+	return cacheResponse(res, ac.client.cache, key)
 }
 
 func (c Client) makePostRequest(uri string, payload []byte) (*http.Response, error) {
+	token, err := c.getAppAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
 	req, err := http.NewRequest("POST", uri, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Client-ID", c.ClientID())
-	req.Header.Set("Accept", "application/vnd.twitchtv.v5+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("Client-Id", c.ClientID())
+	req.Header.Set("Content-Type", "application/json")
 
 	return httpClient.Do(req)
 }
@@ -178,9 +479,8 @@ func (ac AccessClient) makePostRequest(uri string, payload []byte) (*http.Respon
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("OAuth %s", ac.access.Token))
-	req.Header.Set("Client-ID", ac.client.ClientID())
-	req.Header.Set("Accept", "application/vnd.twitchtv.v5+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ac.getAccess().Token))
+	req.Header.Set("Client-Id", ac.client.ClientID())
 	req.Header.Set("Content-Type", "application/json")
 
 	return httpClient.Do(req)
@@ -192,9 +492,8 @@ func (ac AccessClient) makePutRequest(uri string, payload []byte) (*http.Respons
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("OAuth %s", ac.access.Token))
-	req.Header.Set("Client-ID", ac.client.ClientID())
-	req.Header.Set("Accept", "application/vnd.twitchtv.v5+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ac.getAccess().Token))
+	req.Header.Set("Client-Id", ac.client.ClientID())
 	req.Header.Set("Content-Type", "application/json")
 
 	return httpClient.Do(req)
@@ -206,9 +505,8 @@ func (ac AccessClient) makeDeleteRequest(uri string) (*http.Response, error) {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("OAuth %s", ac.access.Token))
-	req.Header.Set("Client-ID", ac.client.ClientID())
-	req.Header.Set("Accept", "application/vnd.twitchtv.v5+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ac.getAccess().Token))
+	req.Header.Set("Client-Id", ac.client.ClientID())
 
 	return httpClient.Do(req)
 }
@@ -225,5 +523,5 @@ func (a Access) ValidateScope(scope string) error {
 
 // helper function to call validateScope directly on the AccessClient.
 func (ac AccessClient) validateScope(scope string) error {
-	return ac.access.ValidateScope(scope)
+	return ac.getAccess().ValidateScope(scope)
 }
@@ -0,0 +1,119 @@
+package twitch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// withTestServer points the package-level baseURI/authURI/httpClient at srv for the duration
+// of fn, restoring the previous values afterward.
+func withTestServer(t *testing.T, srv *httptest.Server, fn func()) {
+	t.Helper()
+
+	origBase, origAuth, origClient := baseURI, authURI, httpClient
+	baseURI = srv.URL + "/helix"
+	authURI = srv.URL + "/oauth2"
+	httpClient = srv.Client()
+
+	defer func() {
+		baseURI, authURI, httpClient = origBase, origAuth, origClient
+	}()
+
+	fn()
+}
+
+// TestMakeGetRequestRefreshPropagatesToEveryHolder reproduces the scenario from the bug report:
+// a 401-triggered refresh must be visible on the *original* AccessClient value the caller is
+// holding, not just inside the method call that performed the refresh. Without that, a second
+// request on the same held AccessClient would retry the refresh using the already-rotated (and
+// by then invalid) refresh token.
+func TestMakeGetRequestRefreshPropagatesToEveryHolder(t *testing.T) {
+	var refreshHits int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/helix/resource", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":[]}`))
+	})
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		refreshHits++
+		json.NewEncoder(w).Encode(tokenResponse{
+			AccessToken:  "new-token",
+			RefreshToken: "new-refresh",
+			ExpiresIn:    3600,
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	withTestServer(t, srv, func() {
+		client := NewClient("id", "secret", "redirect")
+		ac := client.WithAccess(Access{Token: "stale-token", Refresh: "stale-refresh"}).WithNoCache()
+
+		res, err := ac.makeGetRequest(baseURI + "/resource")
+		if err != nil {
+			t.Fatalf("makeGetRequest: %v", err)
+		}
+		res.Body.Close()
+
+		if refreshHits != 1 {
+			t.Fatalf("got %d refresh calls, want 1", refreshHits)
+		}
+
+		if got := ac.getAccess().Token; got != "new-token" {
+			t.Fatalf("original AccessClient's token is %q after refresh, want %q", got, "new-token")
+		}
+
+		// A second call on the same held ac must reuse the already-rotated token instead of
+		// refreshing again with the now-stale refresh token.
+		res, err = ac.makeGetRequest(baseURI + "/resource")
+		if err != nil {
+			t.Fatalf("second makeGetRequest: %v", err)
+		}
+		res.Body.Close()
+
+		if refreshHits != 1 {
+			t.Fatalf("got %d refresh calls after a second request on the same held AccessClient, want 1", refreshHits)
+		}
+	})
+}
+
+func TestMakeGetRequestNoRefreshOnSuccess(t *testing.T) {
+	var refreshHits int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/helix/resource", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data":[]}`))
+	})
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		refreshHits++
+		json.NewEncoder(w).Encode(tokenResponse{AccessToken: "new-token", ExpiresIn: 3600})
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	withTestServer(t, srv, func() {
+		client := NewClient("id", "secret", "redirect")
+		ac := client.WithAccess(Access{Token: "good-token", Refresh: "good-refresh"}).WithNoCache()
+
+		res, err := ac.makeGetRequest(baseURI + "/resource")
+		if err != nil {
+			t.Fatalf("makeGetRequest: %v", err)
+		}
+		res.Body.Close()
+
+		if refreshHits != 0 {
+			t.Fatalf("got %d refresh calls for a request that never 401ed, want 0", refreshHits)
+		}
+	})
+}
@@ -2,170 +2,243 @@ package twitch
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 	"time"
 )
 
 // A User contains all of the data Twitch returns about a given user. Based on the method
 // of retrieval, some of these fields may be omitted.
 type User struct {
-	ID               string        `json:"_id"`
-	Bio              string        `json:"bio"`
-	DisplayName      string        `json:"display_name"`
-	Email            string        `json:"email,omitempty"`
-	EmailVerified    bool          `json:"email_verified,omitempty"`
-	Logo             string        `json:"logo"`
-	Name             string        `json:"name"`
-	Notifications    Notifications `json:"notifications,omitempty"`
-	Partnered        bool          `json:"partnered,omitempty"`
-	TwitterConnected bool          `json:"twitter_connected,omitempty"`
-	Type             string        `json:"type"`
-	CreatedAt        time.Time     `json:"created_at"`
-	UpdatedAt        time.Time     `json:"updated_at"`
-}
-
-type Notifications struct {
-	Email bool `json:"email"`
-	Push  bool `json:"push"`
+	ID              string    `json:"id"`
+	Login           string    `json:"login"`
+	DisplayName     string    `json:"display_name"`
+	Type            string    `json:"type"`
+	BroadcasterType string    `json:"broadcaster_type"`
+	Description     string    `json:"description"`
+	ProfileImageURL string    `json:"profile_image_url"`
+	OfflineImageURL string    `json:"offline_image_url"`
+	ViewCount       uint      `json:"view_count"`
+	Email           string    `json:"email,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
 }
 
 type Subscription struct {
-	ID          string    `json:"_id"`
-	SubPlan     string    `json:"sub_plan"`
-	SubPlanName string    `json:"sub_plan_name"`
-	Channel     Channel   `json:"channel"`
-	CreatedAt   time.Time `json:"created_at"`
+	BroadcasterID    string `json:"broadcaster_id"`
+	BroadcasterLogin string `json:"broadcaster_login"`
+	BroadcasterName  string `json:"broadcaster_name"`
+	GifterID         string `json:"gifter_id"`
+	GifterLogin      string `json:"gifter_login"`
+	GifterName       string `json:"gifter_name"`
+	IsGift           bool   `json:"is_gift"`
+	Tier             string `json:"tier"`
+	PlanName         string `json:"plan_name"`
+	UserID           string `json:"user_id"`
+	UserName         string `json:"user_name"`
+	UserLogin        string `json:"user_login"`
 }
 
 type Follow struct {
-	Notifications bool      `json:"notifications"`
-	Channel       Channel   `json:"channel"`
-	CreatedAt     time.Time `json:"created_at"`
+	UserID     string    `json:"user_id"`
+	UserLogin  string    `json:"user_login"`
+	UserName   string    `json:"user_name"`
+	FollowedAt time.Time `json:"followed_at"`
 }
 
 type Follows struct {
-	Total   uint     `json:"_total"`
-	Follows []Follow `json:"follows"`
-}
-
-type Block struct {
-	ID        string    `json:"_id"`
-	User      User      `json:"user"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Total      uint     `json:"total"`
+	Follows    []Follow `json:"data"`
+	Pagination struct {
+		Cursor string `json:"cursor"`
+	} `json:"pagination"`
 }
 
 // GetUser retrieves the user based on the access token attached to the client.
 func (ac AccessClient) GetUser() (User, error) {
 	var user User
 
-	if err := ac.validateScope("user_read"); err != nil {
-		return user, err
-	}
-
-	uri := fmt.Sprintf("%s/user", baseURI)
+	uri := fmt.Sprintf("%s/users", baseURI)
 
 	res, err := ac.makeGetRequest(uri)
 	if err != nil {
 		return user, err
 	}
 
-	if err := json.NewDecoder(res.Body).Decode(&user); err != nil {
+	if err := checkResponse(res); err != nil {
 		return user, err
 	}
 
-	return user, nil
+	var userRes struct {
+		Data []User `json:"data"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&userRes); err != nil {
+		return user, err
+	}
+
+	if len(userRes.Data) == 0 {
+		return user, fmt.Errorf("no user returned for authorized access token")
+	}
+
+	return userRes.Data[0], nil
+}
+
+// GetAuthorizedUser returns the ID and login of the user that the AccessClient's token
+// belongs to. It replaces the caller-supplied user IDs the Kraken-era API required, since
+// every Helix endpoint that needs a user now derives it from the token itself.
+func (ac AccessClient) GetAuthorizedUser() (id, login string, err error) {
+	user, err := ac.GetUser()
+	if err != nil {
+		return "", "", err
+	}
+
+	return user.ID, user.Login, nil
 }
 
 // GetUserByID retrieves a user based on the given user ID.
 func (c Client) GetUserByID(id string) (User, error) {
 	var user User
-	uri := fmt.Sprintf("%s/user", baseURI)
+	uri := fmt.Sprintf("%s/users?id=%s", baseURI, id)
 
 	res, err := c.makeGetRequest(uri)
 	if err != nil {
 		return user, err
 	}
 
-	if err := json.NewDecoder(res.Body).Decode(&user); err != nil {
+	if err := checkResponse(res); err != nil {
 		return user, err
 	}
 
-	return user, nil
+	var userRes struct {
+		Data []User `json:"data"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&userRes); err != nil {
+		return user, err
+	}
+
+	if len(userRes.Data) == 0 {
+		return user, fmt.Errorf("no user found for id %s", id)
+	}
+
+	return userRes.Data[0], nil
 }
 
 // GetUsersByName accepts a list of usernames to fetch from Twitch. You can include
 // up to 100 names and get a slice of basic user information back, including the ID.
 func (c Client) GetUsersByName(names ...string) ([]User, error) {
 	var userRes struct {
-		Total uint `json:"_total"`
-		Users []User
+		Data []User `json:"data"`
+	}
+
+	query := url.Values{}
+	for _, name := range names {
+		query.Add("login", name)
 	}
 
-	uri := fmt.Sprintf("%s/users?login=%s", baseURI, strings.Join(names, ","))
+	uri := fmt.Sprintf("%s/users?%s", baseURI, query.Encode())
 
 	res, err := c.makeGetRequest(uri)
 	if err != nil {
 		return []User{}, err
 	}
 
+	if err := checkResponse(res); err != nil {
+		return []User{}, err
+	}
+
 	if err := json.NewDecoder(res.Body).Decode(&userRes); err != nil {
 		return []User{}, err
 	}
 
-	return userRes.Users, nil
+	return userRes.Data, nil
 }
 
-// GetUserSubscription returns whether or not a given user ID is subscribed to the given channel ID.
-func (ac AccessClient) GetUserSubscription(userID, channelID string) (Subscription, error) {
+// GetUserSubscription returns whether or not the authorized user is subscribed to the
+// given channel ID.
+func (ac AccessClient) GetUserSubscription(channelID string) (Subscription, error) {
 	var subscription Subscription
-	uri := fmt.Sprintf("%s/users/%s/subscriptions/%s", baseURI, userID, channelID)
 
-	if err := ac.validateScope("user_subscriptions"); err != nil {
+	if err := ac.validateScope("channel:read:subscriptions"); err != nil {
 		return subscription, err
 	}
 
+	userID, _, err := ac.GetAuthorizedUser()
+	if err != nil {
+		return subscription, err
+	}
+
+	query := url.Values{}
+	query.Add("broadcaster_id", channelID)
+	query.Add("user_id", userID)
+
+	uri := fmt.Sprintf("%s/subscriptions?%s", baseURI, query.Encode())
+
 	res, err := ac.makeGetRequest(uri)
 	if err != nil {
 		return subscription, err
 	}
 
-	if res.StatusCode == 404 {
-		return subscription, fmt.Errorf("user is not subscribed")
+	if err := checkResponse(res); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			switch apiErr.Status {
+			case http.StatusNotFound:
+				return subscription, fmt.Errorf("%w: %w", ErrNotSubscribed, apiErr)
+			case http.StatusUnprocessableEntity:
+				return subscription, fmt.Errorf("%w: %w", ErrNoSubProgram, apiErr)
+			}
+		}
+
+		return subscription, err
 	}
 
-	if res.StatusCode == 422 {
-		return subscription, fmt.Errorf("channel does not have a subscription program")
+	var subRes struct {
+		Data []Subscription `json:"data"`
 	}
 
-	if err := json.NewDecoder(res.Body).Decode(&subscription); err != nil {
+	if err := json.NewDecoder(res.Body).Decode(&subRes); err != nil {
 		return subscription, err
 	}
 
-	log.Printf("StatusCode: %d", res.StatusCode)
-	log.Println("MADE IT")
+	if len(subRes.Data) == 0 {
+		return subscription, ErrNotSubscribed
+	}
 
-	return subscription, nil
+	return subRes.Data[0], nil
 }
 
-// GetUserFollows retrieves the list of channels that a given user follows.
-// TODO: Add in support for direction/sortby.
-func (c Client) GetUserFollows(userID string, limit, offset int) (Follows, error) {
+// getChannelFollowers retrieves the followers of broadcasterID, optionally filtered down to
+// a single userID, using the authorized broadcaster's own access token.
+func (ac AccessClient) getChannelFollowers(broadcasterID, userID, cursor string, limit int) (Follows, error) {
 	var follows Follows
+
 	query := url.Values{}
-	query.Add("limit", strconv.Itoa(limit))
-	query.Add("offset", strconv.Itoa(offset))
+	query.Add("broadcaster_id", broadcasterID)
+	if userID != "" {
+		query.Add("user_id", userID)
+	}
+	if limit > 0 {
+		query.Add("first", strconv.Itoa(limit))
+	}
+	if cursor != "" {
+		query.Add("after", cursor)
+	}
 
-	uri := fmt.Sprintf("%s/users/%s/follows/channels?%s", baseURI, userID, query.Encode())
+	uri := fmt.Sprintf("%s/channels/followers?%s", baseURI, query.Encode())
 
-	res, err := c.makeGetRequest(uri)
+	res, err := ac.makeGetRequest(uri)
 	if err != nil {
 		return follows, err
 	}
 
+	if err := checkResponse(res); err != nil {
+		return follows, err
+	}
+
 	if err := json.NewDecoder(res.Body).Decode(&follows); err != nil {
 		return follows, err
 	}
@@ -173,97 +246,62 @@ func (c Client) GetUserFollows(userID string, limit, offset int) (Follows, error
 	return follows, nil
 }
 
-// CheckUserFollowsChannel returns a Follow payload if the user follows the given channel.
-// If the user doesn't follow the channel, an error is returned.
-func (c Client) CheckUserFollowsChannel(userID, channelID string) (Follow, error) {
-	var follow Follow
-
-	uri := fmt.Sprintf("%s/users/%s/follows/channels/%s", baseURI, userID, channelID)
-
-	res, err := c.makeGetRequest(uri)
+// GetUserFollows returns a Paginator walking the authorized broadcaster's followers.
+// TODO: Add in support for direction/sortby.
+func (ac AccessClient) GetUserFollows() (*Paginator[Follow], error) {
+	broadcasterID, _, err := ac.GetAuthorizedUser()
 	if err != nil {
-		return follow, err
-	}
-
-	if res.StatusCode == 404 {
-		return follow, fmt.Errorf("User %s does not follow channel %s", userID, channelID)
+		return nil, err
 	}
 
-	if err := json.NewDecoder(res.Body).Decode(&follow); err != nil {
-		return follow, err
-	}
+	return NewPaginator(func(cursor string) ([]Follow, string, error) {
+		follows, err := ac.getChannelFollowers(broadcasterID, "", cursor, 100)
+		if err != nil {
+			return nil, "", err
+		}
 
-	return follow, nil
+		return follows.Follows, follows.Pagination.Cursor, nil
+	}), nil
 }
 
-func (ac AccessClient) FollowChannel(userID, channelID string, notify bool) (Follow, error) {
-	var follow Follow
-	if err := ac.validateScope("user_follows_edit"); err != nil {
-		return follow, err
-	}
-
-	uri := fmt.Sprintf("%s/users/%s/follows/channels/%s", baseURI, userID, channelID)
-
-	var notifications = struct {
-		Notifications bool `json:"notifications"`
-	}{notify}
-
-	payload, err := json.Marshal(&notifications)
+// CheckUserFollowsChannel returns a Follow payload if the given user follows the authorized
+// broadcaster's channel. If the user doesn't follow the channel, an error is returned.
+func (ac AccessClient) CheckUserFollowsChannel(userID string) (Follow, error) {
+	broadcasterID, _, err := ac.GetAuthorizedUser()
 	if err != nil {
-		return follow, err
+		return Follow{}, err
 	}
 
-	res, err := ac.makePutRequest(uri, payload)
+	follows, err := ac.getChannelFollowers(broadcasterID, userID, "", 1)
 	if err != nil {
-		return follow, err
-	}
-
-	if res.StatusCode == 422 {
-		return follow, fmt.Errorf("User %s could not follow Channel %s", userID, channelID)
+		return Follow{}, err
 	}
 
-	if err := json.NewDecoder(res.Body).Decode(&follow); err != nil {
-		return follow, err
+	if len(follows.Follows) == 0 {
+		return Follow{}, fmt.Errorf("%w: user %s, channel %s", ErrNotFollowing, userID, broadcasterID)
 	}
 
-	return follow, err
+	return follows.Follows[0], nil
 }
 
-func (ac AccessClient) UnfollowChannel(userID, channelID string) error {
-	if err := ac.validateScope("user_follows_edit"); err != nil {
+// BlockUser blocks the given user on behalf of the authorized user. Twitch no longer exposes
+// an API for following/unfollowing channels, so FollowChannel/UnfollowChannel have been
+// retired along with the Kraken migration; blocking is still supported under Helix.
+func (ac AccessClient) BlockUser(blockedID string) error {
+	if err := ac.validateScope("user:manage:blocked_users"); err != nil {
 		return err
 	}
 
-	uri := fmt.Sprintf("%s/users/%s/follows/channels/%s", baseURI, userID, channelID)
+	uri := fmt.Sprintf("%s/users/blocks?target_user_id=%s", baseURI, blockedID)
 
-	res, err := ac.makeDeleteRequest(uri)
+	res, err := ac.makePutRequest(uri, nil)
 	if err != nil {
 		return err
 	}
 
-	if res.StatusCode != 204 {
-		return fmt.Errorf("Failed to unfollow User %s from Channel %s", userID, channelID)
+	if res.StatusCode != http.StatusNoContent {
+		return checkResponse(res)
 	}
 
 	return nil
 }
-
-func (ac AccessClient) BlockUser(userID, blockedID string) (Block, error) {
-	var block Block
-	if err := ac.validateScope("user_blocks_edit"); err != nil {
-		return block, err
-	}
-
-	uri := fmt.Sprintf("%s/users/%s/blocks/%s", baseURI, userID, blockedID)
-
-	res, err := ac.makePutRequest(uri, nil)
-	if err != nil {
-		return block, err
-	}
-
-	if err := json.NewDecoder(res.Body).Decode(&block); err != nil {
-		return block, err
-	}
-
-	return block, nil
-}